@@ -41,98 +41,256 @@ type Encoder interface {
 //
 //	bool
 //	float32 float64
+//	complex64 complex128
 //	int int8 int16 int32 int64
 //	uint uint8 uint16 uint32 uint64
 //	string    (case-sensitive)
 //	time.Time (normalised to UTC)
 //	[]byte    (copied)
-func Encode(v interface{}) (b []byte, err error) {
+//	HalfFloat (e.g. bytesort/half.Float16, bytesort/half.BFloat16)
+//
+// A nil pointer to any of the above, or the Null sentinel, encodes as a
+// sortable NULL; see EncodeWithOptions.
+func Encode(v interface{}) ([]byte, error) {
+	return EncodeWithOptions(v, EncodeOptions{})
+}
+
+// EncodeDesc encodes v like Encode, but the result sorts in the reverse
+// order of Encode.
+//
+// This is done by flipping every bit of the normal encoding, which reverses
+// bytewise comparison while preserving the fixed-length guarantees of
+// Encode. It's useful for composite index keys where some fields need
+// descending order, e.g. (user_id ASC, timestamp DESC); see Key for
+// building such keys.
+//
+// Flipping bits alone does not correctly reverse the order of
+// variable-length values (string, []byte): prefix relationships like
+// ("a" < "ab") survive the flip unchanged. Key.AddDesc avoids this by
+// flipping after escaping and terminating the value, not before.
+func EncodeDesc(v interface{}) ([]byte, error) {
+	return AppendDesc([]byte{}, v)
+}
+
+// AppendDesc is the Append equivalent of EncodeDesc: it encodes v so that it
+// sorts in reverse order, and appends the result to dst.
+func AppendDesc(dst []byte, v interface{}) ([]byte, error) {
+	start := len(dst)
+	dst, err := Append(dst, v)
+	if err != nil {
+		return dst, err
+	}
+	flip(dst[start:])
+	return dst, nil
+}
+
+// Append encodes v as bytewise/binary-sortable bytes and appends them to dst,
+// returning the grown slice.
+//
+// Append follows the same rules and supports the same types as Encode. It
+// exists so that several values can be combined into one buffer, e.g. when
+// building a composite bolt DB key, without the intermediate allocations
+// Encode-and-copy would require.
+func Append(dst []byte, v interface{}) ([]byte, error) {
+	return AppendWithOptions(dst, v, EncodeOptions{})
+}
+
+// appendValue is the core encoder switch shared by Append and
+// AppendWithOptions, without any nullable handling.
+func appendValue(dst []byte, v interface{}) ([]byte, error) {
 	switch vv := v.(type) {
 	case []byte:
-		b := make([]byte, len(vv))
-		copy(b, vv)
-		return b, nil
+		return AppendBytes(dst, vv), nil
 	case string:
-		return []byte(vv), nil
+		return AppendString(dst, vv), nil
 	case time.Time:
-		return encodeTime(vv)
+		return AppendTime(dst, vv)
 	case float64:
-		return encodeFloat64(vv), nil
+		return AppendFloat64(dst, vv), nil
 	case float32:
-		return encodeFloat32(vv), nil
+		return AppendFloat32(dst, vv), nil
+	case complex128:
+		return AppendComplex128(dst, vv), nil
+	case complex64:
+		return AppendComplex64(dst, vv), nil
+	case HalfFloat:
+		return AppendHalfFloat(dst, vv), nil
 	case bool:
-		if vv {
-			return []byte{1}, nil
-		}
-		return []byte{0}, nil
+		return AppendBool(dst, vv), nil
 	case int8:
-		return []byte{byte(vv) ^ 0x80}, nil
+		return AppendInt8(dst, vv), nil
 	case uint8:
-		return []byte{vv}, nil
+		return AppendUint8(dst, vv), nil
 	case int16:
-		b := make([]byte, 2)
-		binary.BigEndian.PutUint16(b, uint16(vv))
-		b[0] ^= 0x80
-		return b, nil
+		return AppendInt16(dst, vv), nil
 	case uint16:
-		b := make([]byte, 2)
-		binary.BigEndian.PutUint16(b, vv)
-		return b, nil
+		return AppendUint16(dst, vv), nil
 	case int32:
-		b := make([]byte, 4)
-		binary.BigEndian.PutUint32(b, uint32(vv))
-		b[0] ^= 0x80
-		return b, nil
+		return AppendInt32(dst, vv), nil
 	case uint32:
-		b := make([]byte, 4)
-		binary.BigEndian.PutUint32(b, vv)
-		return b, nil
+		return AppendUint32(dst, vv), nil
 	case int64:
-		b := make([]byte, 8)
-		binary.BigEndian.PutUint64(b, uint64(vv))
-		b[0] ^= 0x80
-		return b, nil
+		return AppendInt64(dst, vv), nil
 	case uint64:
-		b := make([]byte, 8)
-		binary.BigEndian.PutUint64(b, vv)
-		return b, nil
+		return AppendUint64(dst, vv), nil
 	case int:
-		b := make([]byte, 8)
-		binary.BigEndian.PutUint64(b, uint64(vv))
-		b[0] ^= 0x80
-		return b, nil
+		return AppendInt(dst, vv), nil
 	case uint:
-		b := make([]byte, 8)
-		binary.BigEndian.PutUint64(b, uint64(vv))
-		return b, nil
+		return AppendUint(dst, vv), nil
 	case Encoder:
-		return vv.EncodeSortable()
+		b, err := vv.EncodeSortable()
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, b...), nil
 	}
-	return nil, fmt.Errorf("bytesort.Encode: unsupported type %T", v)
+	return dst, fmt.Errorf("bytesort.Encode: unsupported type %T", v)
+}
+
+// AppendBytes appends a copy of v to dst.
+func AppendBytes(dst, v []byte) []byte {
+	return append(dst, v...)
+}
+
+// AppendString appends v to dst.
+func AppendString(dst []byte, v string) []byte {
+	return append(dst, v...)
+}
+
+// AppendBool appends a single sortable byte to dst.
+func AppendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+// AppendInt8 appends a single sortable byte to dst.
+func AppendInt8(dst []byte, v int8) []byte {
+	return append(dst, byte(v)^0x80)
+}
+
+// AppendUint8 appends a single sortable byte to dst.
+func AppendUint8(dst []byte, v uint8) []byte {
+	return append(dst, v)
 }
 
+// AppendInt16 appends 2 sortable bytes to dst.
+func AppendInt16(dst []byte, v int16) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	b[0] ^= 0x80
+	return append(dst, b[:]...)
+}
+
+// AppendUint16 appends 2 sortable bytes to dst.
+func AppendUint16(dst []byte, v uint16) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	return append(dst, b[:]...)
+}
+
+// AppendInt32 appends 4 sortable bytes to dst.
+func AppendInt32(dst []byte, v int32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	b[0] ^= 0x80
+	return append(dst, b[:]...)
+}
+
+// AppendUint32 appends 4 sortable bytes to dst.
+func AppendUint32(dst []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(dst, b[:]...)
+}
+
+// AppendInt64 appends 8 sortable bytes to dst.
+func AppendInt64(dst []byte, v int64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	b[0] ^= 0x80
+	return append(dst, b[:]...)
+}
+
+// AppendUint64 appends 8 sortable bytes to dst.
+func AppendUint64(dst []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(dst, b[:]...)
+}
+
+// AppendInt appends 8 sortable bytes to dst.
+func AppendInt(dst []byte, v int) []byte {
+	return AppendInt64(dst, int64(v))
+}
+
+// AppendUint appends 8 sortable bytes to dst.
+func AppendUint(dst []byte, v uint) []byte {
+	return AppendUint64(dst, uint64(v))
+}
+
+// AppendFloat64 appends 8 sortable bytes to dst.
+//
 // http://stereopsis.com/radix.html
-func encodeFloat64(v float64) []byte {
+func AppendFloat64(dst []byte, v float64) []byte {
 	bits := math.Float64bits(v)
 	bits ^= -(bits >> 63) | (1 << 63)
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, bits)
-	return b
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], bits)
+	return append(dst, b[:]...)
 }
 
-func encodeFloat32(v float32) []byte {
+// AppendFloat32 appends 4 sortable bytes to dst.
+//
+// http://stereopsis.com/radix.html
+func AppendFloat32(dst []byte, v float32) []byte {
 	bits := math.Float32bits(v)
 	bits ^= -(bits >> 31) | (1 << 31)
-	b := make([]byte, 4)
-	binary.BigEndian.PutUint32(b, bits)
-	return b
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], bits)
+	return append(dst, b[:]...)
+}
+
+// AppendComplex64 appends 8 sortable bytes to dst: the real part followed
+// by the imaginary part, each encoded like AppendFloat32. This gives
+// lexicographic order by real part, then by imaginary part.
+func AppendComplex64(dst []byte, v complex64) []byte {
+	dst = AppendFloat32(dst, real(v))
+	return AppendFloat32(dst, imag(v))
+}
+
+// AppendComplex128 appends 16 sortable bytes to dst: the real part followed
+// by the imaginary part, each encoded like AppendFloat64. This gives
+// lexicographic order by real part, then by imaginary part.
+func AppendComplex128(dst []byte, v complex128) []byte {
+	dst = AppendFloat64(dst, real(v))
+	return AppendFloat64(dst, imag(v))
+}
+
+// HalfFloat is implemented by 16-bit floating point types, such as
+// half.Float16 and half.BFloat16, so they can be encoded by Encode/Append.
+type HalfFloat interface {
+	// Bits returns the type's raw 16-bit encoding.
+	Bits() uint16
+}
+
+// AppendHalfFloat appends 2 sortable bytes to dst, using the same
+// bit-flipping trick as AppendFloat32/AppendFloat64 but on a 16-bit word.
+func AppendHalfFloat(dst []byte, v HalfFloat) []byte {
+	bits := v.Bits()
+	bits ^= -(bits >> 15) | (1 << 15)
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], bits)
+	return append(dst, b[:]...)
 }
 
-func encodeTime(v time.Time) ([]byte, error) {
+// AppendTime appends the sortable encoding of v, normalised to UTC, to dst.
+func AppendTime(dst []byte, v time.Time) ([]byte, error) {
 	b, err := v.MarshalBinary()
 	if err != nil {
-		return nil, fmt.Errorf("bytesort.Encode: %s", err)
+		return dst, fmt.Errorf("bytesort.Encode: %s", err)
 	}
 	// Strip version and time zone, leaving only the sort-relevant parts
-	return b[1 : len(b)-2], nil
+	return append(dst, b[1:len(b)-2]...), nil
 }
@@ -80,6 +80,32 @@ func BenchmarkEncode_parallel(b *testing.B) {
 	}
 }
 
+// BenchmarkAppend_composite compares building a composite key by
+// Encode-ing each field and copying it into a pre-sized buffer against
+// Append-ing each field directly into that buffer.
+func BenchmarkAppend_composite(b *testing.B) {
+	fields := []interface{}{int64(42), "user@example.com", time.Now()}
+	b.Run("Encode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			key := make([]byte, 0, 64)
+			for _, v := range fields {
+				part, _ := bytesort.Encode(v)
+				key = append(key, part...)
+			}
+		}
+	})
+	b.Run("Append", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			key := make([]byte, 0, 64)
+			for _, v := range fields {
+				key, _ = bytesort.Append(key, v)
+			}
+		}
+	})
+}
+
 var encodeErrorTests = []interface{}{
 	nil,
 	[]string{},
@@ -100,6 +126,24 @@ func TestEncode_error(t *testing.T) {
 	}
 }
 
+// TestAppend_errorPreservesDst makes sure a failing Append doesn't discard
+// bytes already written by earlier, successful calls into the same buffer,
+// since that's the entire point of building a composite key across several
+// Append calls.
+func TestAppend_errorPreservesDst(t *testing.T) {
+	dst, err := bytesort.Append(nil, int64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst2, err := bytesort.Append(dst, map[string]string{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !bytes.Equal(dst2, dst) {
+		t.Errorf("got % x, want unchanged % x", dst2, dst)
+	}
+}
+
 type int64Type int64
 
 func (s int64Type) EncodeSortable() ([]byte, error) {
@@ -215,6 +259,24 @@ var sortTests = map[string][]interface{}{
 		false,
 		true,
 	},
+	"complex64": {
+		complex(float32(-2), float32(5)),
+		complex(float32(-1), float32(-3)),
+		complex(float32(-1), float32(0)),
+		complex(float32(-1), float32(2)),
+		complex(float32(0), float32(0)),
+		complex(float32(1), float32(-100)),
+		complex(float32(1), float32(100)),
+	},
+	"complex128": {
+		complex(float64(-2), float64(5)),
+		complex(float64(-1), float64(-3)),
+		complex(float64(-1), float64(0)),
+		complex(float64(-1), float64(2)),
+		complex(float64(0), float64(0)),
+		complex(float64(1), float64(-100)),
+		complex(float64(1), float64(100)),
+	},
 	"string": {
 		"",
 		"  ZOO",
@@ -344,6 +406,59 @@ func TestEncode_fixedLengthExceptForStrings(t *testing.T) {
 	}
 }
 
+func TestEncodeDesc_sortability(t *testing.T) {
+	for typ, values := range sortTests {
+		// EncodeDesc flips bits after encoding, which only reverses
+		// bytewise order for the fixed-length encodings: flipping a
+		// variable-length string's bytes doesn't preserve prefix
+		// relationships, e.g. "" stays sorted before "a" either way.
+		// Key.AddDesc handles variable-length fields correctly by
+		// flipping after the escape/terminator step instead.
+		if typ == "string" {
+			continue
+		}
+		t.Run(typ, func(t *testing.T) {
+			testEncodeDescSortability(t, values)
+		})
+	}
+}
+
+func testEncodeDescSortability(t *testing.T, values []interface{}) {
+	act := make([][]byte, 0, len(values))
+	for _, v := range values {
+		b, err := bytesort.EncodeDesc(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		act = append(act, b)
+	}
+	exp := make([][]byte, len(act))
+	copy(exp, act)
+	sort.Slice(act, func(i, j int) bool {
+		return bytes.Compare(act[i], act[j]) < 0
+	})
+	// EncodeDesc reverses Encode's order, so sorting ascending by bytes
+	// must reverse the original (ascending) order of values.
+	for i, j := 0, len(exp)-1; i < j; i, j = i+1, j-1 {
+		exp[i], exp[j] = exp[j], exp[i]
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Error(pretty.Compare(fmtBytes(act), fmtBytes(exp)))
+	}
+}
+
+func TestAppendDesc(t *testing.T) {
+	dst := []byte{0xAA}
+	got, err := bytesort.AppendDesc(dst, int8(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xAA, 0x7F}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
 func ExampleEncode() {
 	vv := []interface{}{
 		"abc",
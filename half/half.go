@@ -0,0 +1,25 @@
+// Package half provides minimal 16-bit floating point types for use as
+// bytesort.Encode/bytesort.Append input, via the bytesort.HalfFloat
+// interface.
+//
+// Neither type implements arithmetic; they're thin wrappers around a raw
+// 16-bit encoding for callers (e.g. ML/tensor storage) that already have
+// half-precision bits and just need them to be bytewise sortable.
+package half
+
+// Float16 is an IEEE-754 binary16 value, stored as its raw 16-bit encoding.
+type Float16 uint16
+
+// Bits returns the raw 16-bit encoding of f.
+func (f Float16) Bits() uint16 {
+	return uint16(f)
+}
+
+// BFloat16 is a Google Brain bfloat16 value, stored as its raw 16-bit
+// encoding.
+type BFloat16 uint16
+
+// Bits returns the raw 16-bit encoding of f.
+func (f BFloat16) Bits() uint16 {
+	return uint16(f)
+}
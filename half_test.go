@@ -0,0 +1,48 @@
+package bytesort_test
+
+import (
+	"testing"
+
+	"github.com/nochso/bytesort"
+	"github.com/nochso/bytesort/half"
+)
+
+// halfFloatTests lists raw bit patterns in ascending IEEE-754-like order:
+// -Inf, -2, -1, smallest negative, 0, smallest positive, 1, 2, +Inf.
+var halfFloatTests = []uint16{
+	0xFC00,
+	0xC000,
+	0xBC00,
+	0x8001,
+	0x0000,
+	0x0001,
+	0x3C00,
+	0x4000,
+	0x7C00,
+}
+
+func TestEncode_halfFloat(t *testing.T) {
+	var values []interface{}
+	for _, bits := range halfFloatTests {
+		values = append(values, half.Float16(bits))
+	}
+	testEncodeSortability(t, values)
+}
+
+func TestEncode_bfloat16(t *testing.T) {
+	var values []interface{}
+	for _, bits := range halfFloatTests {
+		values = append(values, half.BFloat16(bits))
+	}
+	testEncodeSortability(t, values)
+}
+
+func TestEncode_halfFloat_length(t *testing.T) {
+	b, err := bytesort.Encode(half.Float16(0x3C00))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 2 {
+		t.Errorf("got %d bytes, want 2", len(b))
+	}
+}
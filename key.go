@@ -0,0 +1,242 @@
+package bytesort
+
+import "fmt"
+
+// FieldType identifies the Go type of a value previously added to a Key, so
+// that SplitKey knows how to find the boundary between fields.
+type FieldType int
+
+// Supported field types for use with SplitKey. Each constant corresponds to
+// one of the types accepted by Encode/Append.
+const (
+	Bool FieldType = iota
+	Int8
+	Uint8
+	Int16
+	Uint16
+	Int32
+	Uint32
+	Int64
+	Uint64
+	Int
+	Uint
+	Float32
+	Float64
+	Time
+	String
+	Bytes
+)
+
+// fixedLen returns the encoded width of f, and false if f is variable-length
+// (String or Bytes).
+func (f FieldType) fixedLen() (int, bool) {
+	switch f {
+	case Bool, Int8, Uint8:
+		return 1, true
+	case Int16, Uint16:
+		return 2, true
+	case Int32, Uint32, Float32:
+		return 4, true
+	case Int64, Uint64, Int, Uint, Float64:
+		return 8, true
+	case Time:
+		return 12, true
+	case String, Bytes:
+		return 0, false
+	}
+	return 0, false
+}
+
+// Field describes one value of a Key for use with SplitKey: its type, and
+// whether it was added with AddDesc.
+type Field struct {
+	Type FieldType
+	Desc bool
+}
+
+// Key builds a bytewise-sortable composite key out of several values, for
+// use as e.g. a bolt DB index key made up of multiple fields.
+//
+// Fixed-length values (the numeric/bool/time encodings produced by Encode)
+// are appended as-is. Variable-length values (string and []byte) are
+// escaped so that the key remains correctly sortable even when fields of
+// different lengths are mixed: every 0x00 byte in the encoded value is
+// replaced by the two-byte sequence 0x00 0xFF, and the field is terminated
+// by the two-byte sequence 0x00 0x00. This is a variant of the escaping
+// scheme used by FoundationDB's tuple layer and by SQLite index keys; the
+// terminator is two bytes, not one, specifically so that SplitKey can find
+// field boundaries unambiguously: a lone, un-escaped 0x00 can otherwise be
+// confused for the start of an escape pair when it happens to be followed
+// by a byte from the next field that equals 0xFF. Since every literal 0x00
+// in a field's own payload is always escaped to 0x00 0xFF, two consecutive
+// 0x00 bytes can only ever be the terminator, never part of a payload.
+type Key struct {
+	buf []byte
+}
+
+// NewKey returns an empty Key ready to Add values to.
+func NewKey() *Key {
+	return &Key{}
+}
+
+// Add encodes v in ascending order and appends it to the key.
+func (k *Key) Add(v interface{}) error {
+	return k.add(v, false)
+}
+
+// AddDesc encodes v so that it sorts in descending order within the key,
+// by flipping every bit of its encoding. This lets composite keys mix
+// ascending and descending fields, e.g. (user_id ASC, timestamp DESC).
+func (k *Key) AddDesc(v interface{}) error {
+	return k.add(v, true)
+}
+
+func (k *Key) add(v interface{}, desc bool) error {
+	switch vv := v.(type) {
+	case string:
+		k.addVariable([]byte(vv), desc)
+		return nil
+	case []byte:
+		k.addVariable(vv, desc)
+		return nil
+	case *string:
+		if vv == nil {
+			k.addNullableVariable(nil, false, desc)
+		} else {
+			k.addNullableVariable([]byte(*vv), true, desc)
+		}
+		return nil
+	case *[]byte:
+		if vv == nil {
+			k.addNullableVariable(nil, false, desc)
+		} else {
+			k.addNullableVariable(*vv, true, desc)
+		}
+		return nil
+	}
+	var err error
+	if desc {
+		k.buf, err = AppendDesc(k.buf, v)
+	} else {
+		k.buf, err = Append(k.buf, v)
+	}
+	return err
+}
+
+func (k *Key) addVariable(v []byte, desc bool) {
+	k.buf = appendVariable(k.buf, v, desc)
+}
+
+// addNullableVariable appends a nullable variable-length field: a NULL tag
+// byte if present is false (the original pointer was nil), or a non-NULL
+// tag byte followed by v escaped and terminated like addVariable. Routing
+// through appendVariable (rather than the plain Append a nil/non-nil
+// pointer would otherwise take) is what keeps a nullable string/[]byte
+// field in a composite key sortable regardless of its length; see
+// fieldEncoder in struct.go for the same fix applied to struct fields.
+func (k *Key) addNullableVariable(v []byte, present, desc bool) {
+	nullTag, valueTag := nullTags(EncodeOptions{})
+	start := len(k.buf)
+	if !present {
+		k.buf = append(k.buf, nullTag)
+	} else {
+		k.buf = append(k.buf, valueTag)
+		k.buf = appendVariable(k.buf, v, false)
+	}
+	if desc {
+		flip(k.buf[start:])
+	}
+}
+
+// appendVariable escapes v and appends it followed by a 0x00 0x00
+// terminator to dst. The terminator sorts strictly below any escaped
+// payload byte (an escaped literal 0x00 is always followed by 0xFF, never
+// by another 0x00), which is what keeps the whole key correctly sortable
+// regardless of field lengths. If desc is true, the escaped field is
+// bit-flipped so it sorts in descending order.
+func appendVariable(dst, v []byte, desc bool) []byte {
+	start := len(dst)
+	for _, c := range v {
+		if c == 0x00 {
+			dst = append(dst, 0x00, 0xFF)
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	dst = append(dst, 0x00, 0x00)
+	if desc {
+		flip(dst[start:])
+	}
+	return dst
+}
+
+// Bytes returns the bytewise-sortable key built so far.
+func (k *Key) Bytes() []byte {
+	return k.buf
+}
+
+func flip(b []byte) {
+	for i := range b {
+		b[i] ^= 0xFF
+	}
+}
+
+// SplitKey walks a key produced by Key given the schema describing each of
+// its fields in order, and returns the still-encoded bytes of each field.
+// This lets callers recover field boundaries for range scans without
+// knowing the values ahead of time.
+func SplitKey(schema []Field, key []byte) ([][]byte, error) {
+	fields := make([][]byte, 0, len(schema))
+	for i, f := range schema {
+		if n, ok := f.Type.fixedLen(); ok {
+			if len(key) < n {
+				return nil, fmt.Errorf("bytesort.SplitKey: field %d: want %d bytes, only %d left", i, n, len(key))
+			}
+			fields = append(fields, key[:n])
+			key = key[n:]
+			continue
+		}
+		end, err := findTerminator(key, f.Desc)
+		if err != nil {
+			return nil, fmt.Errorf("bytesort.SplitKey: field %d: %s", i, err)
+		}
+		fields = append(fields, key[:end])
+		key = key[end+2:]
+	}
+	if len(key) != 0 {
+		return nil, fmt.Errorf("bytesort.SplitKey: %d trailing bytes after last field", len(key))
+	}
+	return fields, nil
+}
+
+// findTerminator returns the index of the two-byte terminator of a
+// variable-length field: 0x00 0x00 for an ascending field, or the
+// bit-flipped equivalent 0xFF 0xFF for a descending one.
+//
+// Scanning byte-by-byte for the first tag byte (0x00, or 0xFF if desc) and
+// then looking at the following byte is unambiguous: within the field's
+// own payload, a tag byte is always immediately followed by the escape
+// byte (the opposite tag), never by another tag byte, since every literal
+// occurrence of the tag byte was escaped during encoding. Two consecutive
+// tag bytes can therefore only be the terminator, even when the very next
+// field happens to start with the escape byte.
+func findTerminator(b []byte, desc bool) (int, error) {
+	term, escape := byte(0x00), byte(0xFF)
+	if desc {
+		term, escape = 0xFF, 0x00
+	}
+	for i := 0; i+1 < len(b); i++ {
+		if b[i] != term {
+			continue
+		}
+		switch b[i+1] {
+		case term:
+			return i, nil
+		case escape:
+			i++ // skip escaped pair
+		default:
+			return 0, fmt.Errorf("malformed field: %#02x at offset %d not followed by %#02x or %#02x", b[i], i, term, escape)
+		}
+	}
+	return 0, fmt.Errorf("missing terminator")
+}
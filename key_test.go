@@ -0,0 +1,242 @@
+package bytesort_test
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/nochso/bytesort"
+)
+
+func TestKey_sortability(t *testing.T) {
+	type tuple struct {
+		a string
+		b int64
+	}
+	tuples := []tuple{
+		{"a", 2},
+		{"ab", 1},
+		{"", 0},
+		{"a", 0},
+		{"aa", -1},
+	}
+	var keys [][]byte
+	for _, tp := range tuples {
+		k := bytesort.NewKey()
+		if err := k.Add(tp.a); err != nil {
+			t.Fatal(err)
+		}
+		if err := k.Add(tp.b); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k.Bytes())
+	}
+	sorted := make([][]byte, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	// ("", 0) < ("a", 0) < ("a", 2) < ("aa", -1) < ("ab", 1)
+	want := [][]byte{keys[2], keys[3], keys[0], keys[4], keys[1]}
+	for i := range want {
+		if !bytes.Equal(sorted[i], want[i]) {
+			t.Errorf("position %d: got % x, want % x", i, sorted[i], want[i])
+		}
+	}
+}
+
+func TestKey_AddDesc(t *testing.T) {
+	asc := bytesort.NewKey()
+	if err := asc.Add(int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := asc.Add(int64(2)); err != nil {
+		t.Fatal(err)
+	}
+	desc1 := bytesort.NewKey()
+	if err := desc1.AddDesc(int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	desc2 := bytesort.NewKey()
+	if err := desc2.AddDesc(int64(2)); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(desc2.Bytes(), desc1.Bytes()) >= 0 {
+		t.Error("AddDesc(2) should sort before AddDesc(1)")
+	}
+}
+
+func TestKey_nullByteInString(t *testing.T) {
+	k := bytesort.NewKey()
+	if err := k.Add("a\x00b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add(int8(5)); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'a', 0x00, 0xFF, 'b', 0x00, 0x00, 0x85}
+	if !bytes.Equal(k.Bytes(), want) {
+		t.Errorf("got % x, want % x", k.Bytes(), want)
+	}
+}
+
+// TestKey_nullableStringPointer makes sure Key.Add routes a *string through
+// the same escape/terminate logic as a plain string, so a nullable string
+// field in a composite key stays sortable regardless of its length.
+func TestKey_nullableStringPointer(t *testing.T) {
+	a, ab := "a", "ab"
+	k1 := bytesort.NewKey()
+	_ = k1.Add(&a)
+	_ = k1.Add(int64(2))
+	k2 := bytesort.NewKey()
+	_ = k2.Add(&ab)
+	_ = k2.Add(int64(1))
+	if bytes.Compare(k1.Bytes(), k2.Bytes()) >= 0 {
+		t.Errorf(`("a", 2) should sort before ("ab", 1), got % x >= % x`, k1.Bytes(), k2.Bytes())
+	}
+
+	var nilStr *string
+	k3 := bytesort.NewKey()
+	_ = k3.Add(nilStr)
+	_ = k3.Add(int64(1))
+	if bytes.Compare(k3.Bytes(), k1.Bytes()) >= 0 {
+		t.Error("a nil *string should sort before a non-nil one")
+	}
+}
+
+// TestKey_nullableBytesPointer is the []byte counterpart of
+// TestKey_nullableStringPointer.
+func TestKey_nullableBytesPointer(t *testing.T) {
+	a, ab := []byte("a"), []byte("ab")
+	k1 := bytesort.NewKey()
+	_ = k1.Add(&a)
+	_ = k1.Add(int64(2))
+	k2 := bytesort.NewKey()
+	_ = k2.Add(&ab)
+	_ = k2.Add(int64(1))
+	if bytes.Compare(k1.Bytes(), k2.Bytes()) >= 0 {
+		t.Errorf(`("a", 2) should sort before ("ab", 1), got % x >= % x`, k1.Bytes(), k2.Bytes())
+	}
+
+	var nilBytes *[]byte
+	k3 := bytesort.NewKey()
+	_ = k3.Add(nilBytes)
+	_ = k3.Add(int64(1))
+	if bytes.Compare(k3.Bytes(), k1.Bytes()) >= 0 {
+		t.Error("a nil *[]byte should sort before a non-nil one")
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	k := bytesort.NewKey()
+	if err := k.Add("ab"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add(int64(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add("c"); err != nil {
+		t.Fatal(err)
+	}
+	schema := []bytesort.Field{
+		{Type: bytesort.String},
+		{Type: bytesort.Int64},
+		{Type: bytesort.String},
+	}
+	fields, err := bytesort.SplitKey(schema, k.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fields[0]) != "ab" {
+		t.Errorf("field 0: got %q, want %q", fields[0], "ab")
+	}
+	if string(fields[2]) != "c" {
+		t.Errorf("field 2: got %q, want %q", fields[2], "c")
+	}
+	want, _ := bytesort.Encode(int64(42))
+	if !bytes.Equal(fields[1], want) {
+		t.Errorf("field 1: got % x, want % x", fields[1], want)
+	}
+}
+
+// TestSplitKey_escapeByteAtFieldBoundary makes sure a variable-length
+// field's terminator isn't confused for the start of an escape pair when
+// the next field's leading byte happens to equal the escape byte (0xFF).
+func TestSplitKey_escapeByteAtFieldBoundary(t *testing.T) {
+	k := bytesort.NewKey()
+	if err := k.Add("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add([]byte{0xFF, 0x42}); err != nil {
+		t.Fatal(err)
+	}
+	schema := []bytesort.Field{
+		{Type: bytesort.Bytes},
+		{Type: bytesort.Bytes},
+	}
+	fields, err := bytesort.SplitKey(schema, k.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fields[0]) != "a" {
+		t.Errorf("field 0: got %q, want %q", fields[0], "a")
+	}
+	if !bytes.Equal(fields[1], []byte{0xFF, 0x42}) {
+		t.Errorf("field 1: got % x, want % x", fields[1], []byte{0xFF, 0x42})
+	}
+}
+
+// TestSplitKey_stringFollowedByLargeInt mirrors the common case of a
+// variable-length field directly followed by a fixed-length field whose
+// first encoded byte is 0xFF (e.g. a large int64).
+func TestSplitKey_stringFollowedByLargeInt(t *testing.T) {
+	k := bytesort.NewKey()
+	if err := k.Add("ab"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add(int64(math.MaxInt64)); err != nil {
+		t.Fatal(err)
+	}
+	schema := []bytesort.Field{
+		{Type: bytesort.String},
+		{Type: bytesort.Int64},
+	}
+	fields, err := bytesort.SplitKey(schema, k.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fields[0]) != "ab" {
+		t.Errorf("field 0: got %q, want %q", fields[0], "ab")
+	}
+	want, _ := bytesort.Encode(int64(math.MaxInt64))
+	if !bytes.Equal(fields[1], want) {
+		t.Errorf("field 1: got % x, want % x", fields[1], want)
+	}
+}
+
+func TestSplitKey_desc(t *testing.T) {
+	k := bytesort.NewKey()
+	if err := k.AddDesc("ab"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Add(int64(7)); err != nil {
+		t.Fatal(err)
+	}
+	schema := []bytesort.Field{
+		{Type: bytesort.String, Desc: true},
+		{Type: bytesort.Int64},
+	}
+	fields, err := bytesort.SplitKey(schema, k.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("got %d fields, want 2", len(fields))
+	}
+	want, _ := bytesort.Encode(int64(7))
+	if !bytes.Equal(fields[1], want) {
+		t.Errorf("field 1: got % x, want % x", fields[1], want)
+	}
+}
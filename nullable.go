@@ -0,0 +1,91 @@
+package bytesort
+
+import "reflect"
+
+// EncodeOptions controls optional Encode/Append behaviour. Currently the
+// only option is how NULL values are ordered; see NullsLast.
+type EncodeOptions struct {
+	// NullsLast sorts NULL values after non-null values of the same type.
+	// The default, false, sorts NULL values first.
+	NullsLast bool
+}
+
+// Null is a sentinel value representing an explicit NULL. Pass it to
+// Encode/Append where a typed nil pointer isn't available.
+type Null struct{}
+
+// NullableEncoder may be implemented in addition to Encoder by a type that
+// wants a dedicated sortable encoding for its own NULL value, instead of
+// relying on the generic tag-only encoding used for a nil pointer or Null.
+type NullableEncoder interface {
+	Encoder
+	// EncodeSortableNull encodes a NULL value of the receiver's type.
+	EncodeSortableNull() ([]byte, error)
+}
+
+// EncodeWithOptions is Encode with explicit control over null ordering; see
+// EncodeOptions.
+func EncodeWithOptions(v interface{}, opts EncodeOptions) ([]byte, error) {
+	return AppendWithOptions([]byte{}, v, opts)
+}
+
+// AppendWithOptions is Append with explicit control over null ordering; see
+// EncodeOptions.
+//
+// A nil pointer (e.g. (*int64)(nil)) or the Null sentinel is encoded as a
+// single NULL tag byte: 0x00 if opts.NullsLast is false (nulls sort
+// first), or 0xFF if it's true (nulls sort last). A non-nil pointer is
+// encoded as the opposite tag byte, followed by the sortable encoding of
+// the pointed-to value. Because the tag byte always differs between null
+// and non-null, every null sorts before or after every non-null value of
+// the same type regardless of the value's own length.
+func AppendWithOptions(dst []byte, v interface{}, opts EncodeOptions) ([]byte, error) {
+	nullTag, valueTag := nullTags(opts)
+
+	if _, ok := v.(Null); ok {
+		return append(dst, nullTag), nil
+	}
+
+	if v != nil {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if ne, ok := v.(NullableEncoder); ok {
+					b, err := ne.EncodeSortableNull()
+					if err != nil {
+						return dst, err
+					}
+					return append(append(dst, nullTag), b...), nil
+				}
+				return append(dst, nullTag), nil
+			}
+			tagged := append(dst, valueTag)
+			// Check v itself for Encoder before dereferencing: a type
+			// commonly implements EncodeSortable with a pointer
+			// receiver, in which case only the pointer (not the
+			// pointed-to value) satisfies Encoder.
+			if enc, ok := v.(Encoder); ok {
+				b, err := enc.EncodeSortable()
+				if err != nil {
+					return dst, err
+				}
+				return append(tagged, b...), nil
+			}
+			out, err := appendValue(tagged, rv.Elem().Interface())
+			if err != nil {
+				return dst, err
+			}
+			return out, nil
+		}
+	}
+	return appendValue(dst, v)
+}
+
+// nullTags returns the (nullTag, valueTag) byte pair used to tag a nullable
+// value under opts: nullTag sorts first unless opts.NullsLast, in which
+// case the pair is swapped.
+func nullTags(opts EncodeOptions) (nullTag, valueTag byte) {
+	if opts.NullsLast {
+		return 0xFF, 0x00
+	}
+	return 0x00, 0xFF
+}
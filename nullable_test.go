@@ -0,0 +1,164 @@
+package bytesort_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/nochso/bytesort"
+)
+
+func TestEncode_nilPointer(t *testing.T) {
+	var p *int64
+	b, err := bytesort.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x00}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+func TestEncode_nonNilPointer(t *testing.T) {
+	v := int64(5)
+	b, err := bytesort.Encode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := bytesort.Encode(v)
+	want := append([]byte{0xFF}, plain...)
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+func TestEncode_Null(t *testing.T) {
+	b, err := bytesort.Encode(bytesort.Null{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x00}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+func TestEncodeWithOptions_nullsLast(t *testing.T) {
+	var p *int64
+	b, err := bytesort.EncodeWithOptions(p, bytesort.EncodeOptions{NullsLast: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xFF}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+// sentinelID implements bytesort.NullableEncoder so that a nil *sentinelID
+// encodes as a dedicated sentinel value instead of the generic single-byte
+// NULL tag.
+type sentinelID int64
+
+func (s *sentinelID) EncodeSortable() ([]byte, error) {
+	return bytesort.Encode(int64(*s))
+}
+
+func (s *sentinelID) EncodeSortableNull() ([]byte, error) {
+	return []byte{0xAA}, nil
+}
+
+func TestAppendWithOptions_nullableEncoder(t *testing.T) {
+	var p *sentinelID
+	b, err := bytesort.AppendWithOptions(nil, p, bytesort.EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x00, 0xAA}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+// TestAppendWithOptions_nonNilPointerEncoder makes sure a non-nil pointer
+// whose Encoder is implemented with a pointer receiver (the common case,
+// and the shape sentinelID itself uses) is dispatched through that
+// Encoder, rather than being dereferenced first and re-encoded as its
+// underlying type, which wouldn't implement Encoder at all.
+func TestAppendWithOptions_nonNilPointerEncoder(t *testing.T) {
+	id := sentinelID(7)
+	b, err := bytesort.AppendWithOptions(nil, &id, bytesort.EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte{0xFF}, mustEncode(t, int64(7))...)
+	if !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+}
+
+func mustEncode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := bytesort.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestEncode_pointerSortability mixes nil, zero and non-zero *int64 values
+// and checks that nils sort together, either first (default) or last
+// (NullsLast), regardless of the pointed-to value.
+func TestEncode_pointerSortability(t *testing.T) {
+	zero, neg, pos := int64(0), int64(-5), int64(5)
+	values := []*int64{&pos, nil, &zero, nil, &neg}
+
+	for _, tc := range []struct {
+		name      string
+		nullsLast bool
+	}{
+		{"NullsFirst", false},
+		{"NullsLast", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := bytesort.EncodeOptions{NullsLast: tc.nullsLast}
+			encoded := make([][]byte, len(values))
+			for i, v := range values {
+				b, err := bytesort.EncodeWithOptions(v, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				encoded[i] = b
+			}
+			sorted := make([][]byte, len(encoded))
+			copy(sorted, encoded)
+			sort.Slice(sorted, func(i, j int) bool {
+				return bytes.Compare(sorted[i], sorted[j]) < 0
+			})
+
+			nils := 0
+			for _, b := range sorted {
+				if bytes.Equal(b, []byte{0x00}) || bytes.Equal(b, []byte{0xFF}) {
+					nils++
+				}
+			}
+			if nils != 2 {
+				t.Fatalf("expected 2 null encodings, got %d", nils)
+			}
+			nullTag := byte(0x00)
+			if tc.nullsLast {
+				nullTag = 0xFF
+			}
+			checkRange := sorted[:2]
+			if tc.nullsLast {
+				checkRange = sorted[len(sorted)-2:]
+			}
+			for _, b := range checkRange {
+				if len(b) != 1 || b[0] != nullTag {
+					t.Errorf("expected lone null tag %#x, got % x", nullTag, b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package bytesort
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EncodeStruct encodes the exported fields of the struct v (or the struct
+// pointed to by v) in declaration order, concatenating their sortable
+// encodings. Variable-length fields (string, []byte) are escaped the same
+// way Key does it, so composite encodings stay sortable regardless of
+// field lengths.
+//
+// Fields can be tuned with a `bytesort` struct tag, whose value is a
+// comma-separated list of:
+//
+//	desc     encode the field so it sorts in descending order
+//	nullable accept a nil pointer or the Null sentinel for this field
+//	skip     omit the field entirely
+func EncodeStruct(v interface{}) ([]byte, error) {
+	return AppendStruct(nil, v)
+}
+
+// AppendStruct is the Append equivalent of EncodeStruct.
+func AppendStruct(dst []byte, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return dst, fmt.Errorf("bytesort.EncodeStruct: %T is not a struct", v)
+	}
+	l, err := getLayout(rv.Type())
+	if err != nil {
+		return dst, err
+	}
+	return l.append(dst, rv)
+}
+
+// EncodeSlice encodes each element of the slice v (elements may be structs
+// or pointers to structs) one after another, using a single cached field
+// layout shared across every element.
+func EncodeSlice(v interface{}) ([]byte, error) {
+	return AppendSlice(nil, v)
+}
+
+// AppendSlice is the Append equivalent of EncodeSlice.
+func AppendSlice(dst []byte, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return dst, fmt.Errorf("bytesort.EncodeSlice: %T is not a slice", v)
+	}
+	if rv.Len() == 0 {
+		return dst, nil
+	}
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	l, err := getLayout(elemType)
+	if err != nil {
+		return dst, err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		ev := rv.Index(i)
+		for ev.Kind() == reflect.Ptr {
+			if ev.IsNil() {
+				return dst, fmt.Errorf("bytesort.EncodeSlice: element %d is a nil pointer", i)
+			}
+			ev = ev.Elem()
+		}
+		dst, err = l.append(dst, ev)
+		if err != nil {
+			return dst, fmt.Errorf("bytesort.EncodeSlice: element %d: %s", i, err)
+		}
+	}
+	return dst, nil
+}
+
+// fieldLayout is a precomputed descriptor for one encodable struct field.
+type fieldLayout struct {
+	index    int
+	name     string
+	desc     bool
+	nullable bool
+	encode   func(dst []byte, fv reflect.Value) ([]byte, error)
+}
+
+// structLayout is the cached set of fieldLayouts for one struct type.
+type structLayout struct {
+	fields []fieldLayout
+}
+
+func (l *structLayout) append(dst []byte, rv reflect.Value) ([]byte, error) {
+	for _, f := range l.fields {
+		start := len(dst)
+		var err error
+		dst, err = f.encode(dst, rv.Field(f.index))
+		if err != nil {
+			return dst, fmt.Errorf("bytesort: field %s: %s", f.name, err)
+		}
+		if f.desc {
+			flip(dst[start:])
+		}
+	}
+	return dst, nil
+}
+
+// layoutCache maps a struct's reflect.Type to its precomputed structLayout,
+// so repeated calls to EncodeStruct/EncodeSlice for the same type only pay
+// the cost of reflecting over its fields once.
+var layoutCache sync.Map // map[reflect.Type]*structLayout
+
+func getLayout(t reflect.Type) (*structLayout, error) {
+	if cached, ok := layoutCache.Load(t); ok {
+		return cached.(*structLayout), nil
+	}
+	l, err := buildLayout(t)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := layoutCache.LoadOrStore(t, l)
+	return actual.(*structLayout), nil
+}
+
+func buildLayout(t reflect.Type) (*structLayout, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bytesort: %s is not a struct", t)
+	}
+	l := &structLayout{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		var desc, nullable, skip bool
+		for _, opt := range strings.Split(sf.Tag.Get("bytesort"), ",") {
+			switch opt {
+			case "desc":
+				desc = true
+			case "nullable":
+				nullable = true
+			case "skip":
+				skip = true
+			}
+		}
+		if skip {
+			continue
+		}
+		l.fields = append(l.fields, fieldLayout{
+			index:    i,
+			name:     sf.Name,
+			desc:     desc,
+			nullable: nullable,
+			encode:   fieldEncoder(sf.Type, nullable),
+		})
+	}
+	return l, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isVariableLength reports whether t is a string or []byte, the two types
+// fieldEncoder routes through appendVariable's escape/terminate logic
+// rather than a plain fixed-width Append.
+func isVariableLength(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// fieldEncoder returns the closure used to encode every field of type t,
+// so that AppendStruct/AppendSlice don't need to re-inspect the field's
+// type on every call.
+func fieldEncoder(t reflect.Type, nullable bool) func(dst []byte, fv reflect.Value) ([]byte, error) {
+	switch {
+	case isVariableLength(t):
+		return func(dst []byte, fv reflect.Value) ([]byte, error) {
+			return appendVariable(dst, toBytes(fv), false), nil
+		}
+	case nullable && t.Kind() == reflect.Ptr && isVariableLength(t.Elem()):
+		// A nullable string/[]byte pointer still has to go through
+		// appendVariable's escape/terminate logic, not the raw
+		// AppendString/AppendBytes that the generic nullable path
+		// below would reach via AppendWithOptions/appendValue.
+		// Otherwise the tuple sortability guarantee above breaks as
+		// soon as one field in the composite key is both nullable and
+		// variable-length.
+		nullTag, valueTag := nullTags(EncodeOptions{})
+		return func(dst []byte, fv reflect.Value) ([]byte, error) {
+			if fv.IsNil() {
+				return append(dst, nullTag), nil
+			}
+			dst = append(dst, valueTag)
+			return appendVariable(dst, toBytes(fv.Elem()), false), nil
+		}
+	case nullable:
+		return func(dst []byte, fv reflect.Value) ([]byte, error) {
+			return AppendWithOptions(dst, fv.Interface(), EncodeOptions{})
+		}
+	default:
+		return func(dst []byte, fv reflect.Value) ([]byte, error) {
+			return Append(dst, fv.Interface())
+		}
+	}
+}
+
+// toBytes returns the raw bytes of fv, a string or []byte value.
+func toBytes(fv reflect.Value) []byte {
+	if fv.Kind() == reflect.String {
+		return []byte(fv.String())
+	}
+	return fv.Bytes()
+}
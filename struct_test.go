@@ -0,0 +1,270 @@
+package bytesort_test
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nochso/bytesort"
+)
+
+type event struct {
+	UserID    int64
+	CreatedAt int64 `bytesort:"desc"`
+	Note      string
+	Internal  string `bytesort:"skip"`
+}
+
+func TestEncodeStruct(t *testing.T) {
+	e := event{UserID: 1, CreatedAt: 100, Note: "hi", Internal: "ignored"}
+	got, err := bytesort.EncodeStruct(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := bytesort.NewKey()
+	_ = k.Add(e.UserID)
+	_ = k.AddDesc(e.CreatedAt)
+	_ = k.Add(e.Note)
+	want := k.Bytes()
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestEncodeStruct_pointer(t *testing.T) {
+	e := &event{UserID: 1, CreatedAt: 100, Note: "hi"}
+	got, err := bytesort.EncodeStruct(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := bytesort.EncodeStruct(*e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestEncodeStruct_notAStruct(t *testing.T) {
+	_, err := bytesort.EncodeStruct(42)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestEncodeStruct_sortability(t *testing.T) {
+	events := []event{
+		{UserID: 1, CreatedAt: 200, Note: "a"},
+		{UserID: 1, CreatedAt: 100, Note: "a"},
+		{UserID: 2, CreatedAt: 50, Note: "z"},
+	}
+	var encoded [][]byte
+	for _, e := range events {
+		b, err := bytesort.EncodeStruct(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, b)
+	}
+	sorted := make([][]byte, len(encoded))
+	copy(sorted, encoded)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	// UserID ASC, CreatedAt DESC: (1,200), (1,100), (2,50)
+	want := [][]byte{encoded[0], encoded[1], encoded[2]}
+	for i := range want {
+		if !bytes.Equal(sorted[i], want[i]) {
+			t.Errorf("position %d: got % x, want % x", i, sorted[i], want[i])
+		}
+	}
+}
+
+type nullableEvent struct {
+	UserID int64
+	Amount *int64 `bytesort:"nullable"`
+}
+
+func TestEncodeStruct_nullable(t *testing.T) {
+	amount := int64(50)
+	withAmount := nullableEvent{UserID: 1, Amount: &amount}
+	withoutAmount := nullableEvent{UserID: 1, Amount: nil}
+
+	gotWith, err := bytesort.EncodeStruct(withAmount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotWithout, err := bytesort.EncodeStruct(withoutAmount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(gotWithout, gotWith) >= 0 {
+		t.Error("nil Amount should sort before a non-nil Amount")
+	}
+}
+
+type nullableTagEvent struct {
+	Tag  *string `bytesort:"nullable"`
+	Rest int64
+}
+
+// TestEncodeStruct_nullableVariableLength makes sure a nullable string/[]byte
+// field still gets escaped and terminated like a plain one, so that a
+// composite key stays sortable regardless of the field's length.
+func TestEncodeStruct_nullableVariableLength(t *testing.T) {
+	a, ab := "a", "ab"
+	shorter := nullableTagEvent{Tag: &a, Rest: 2}
+	longer := nullableTagEvent{Tag: &ab, Rest: 1}
+
+	gotShorter, err := bytesort.EncodeStruct(shorter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotLonger, err := bytesort.EncodeStruct(longer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(gotShorter, gotLonger) >= 0 {
+		t.Errorf(`("a", 2) should sort before ("ab", 1), got % x >= % x`, gotShorter, gotLonger)
+	}
+
+	nilTag := nullableTagEvent{Tag: nil, Rest: 1}
+	gotNil, err := bytesort.EncodeStruct(nilTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(gotNil, gotShorter) >= 0 {
+		t.Error("nil Tag should sort before a non-nil Tag")
+	}
+}
+
+func TestEncodeSlice(t *testing.T) {
+	events := []event{
+		{UserID: 1, CreatedAt: 100, Note: "a"},
+		{UserID: 2, CreatedAt: 200, Note: "b"},
+	}
+	got, err := bytesort.EncodeSlice(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []byte
+	for _, e := range events {
+		want, err = bytesort.AppendStruct(want, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestEncodeSlice_pointers(t *testing.T) {
+	events := []*event{
+		{UserID: 1, CreatedAt: 100, Note: "a"},
+		{UserID: 2, CreatedAt: 200, Note: "b"},
+	}
+	got, err := bytesort.EncodeSlice(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []event{*events[0], *events[1]}
+	want, err := bytesort.EncodeSlice(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+// TestEncodeSlice_nilPointer makes sure a nil element in a []*T slice
+// returns a descriptive error instead of panicking.
+func TestEncodeSlice_nilPointer(t *testing.T) {
+	events := []*event{
+		{UserID: 1, CreatedAt: 100, Note: "a"},
+		nil,
+	}
+	_, err := bytesort.EncodeSlice(events)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+// naiveEncodeStruct re-reflects over the struct's fields on every call
+// instead of consulting bytesort's layout cache, the way a naive
+// reflect-every-call implementation would.
+func naiveEncodeStruct(e interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(e)
+	t := rv.Type()
+	var dst []byte
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := sf.Tag.Get("bytesort")
+		if strings.Contains(tag, "skip") {
+			continue
+		}
+		b, err := bytesort.Encode(rv.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(tag, "desc") {
+			b, err = bytesort.EncodeDesc(rv.Field(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, b...)
+	}
+	return dst, nil
+}
+
+func BenchmarkEncodeStruct(b *testing.B) {
+	e := event{UserID: 1, CreatedAt: 100, Note: "hi"}
+	b.Run("cached layout", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bytesort.EncodeStruct(e)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			naiveEncodeStruct(e)
+		}
+	})
+}
+
+func BenchmarkEncodeSlice(b *testing.B) {
+	events := make([]event, 100)
+	for i := range events {
+		events[i] = event{UserID: int64(i), CreatedAt: int64(i * 2), Note: "hi"}
+	}
+	b.Run("cached layout", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bytesort.EncodeSlice(events)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var dst []byte
+			for _, e := range events {
+				eb, err := naiveEncodeStruct(e)
+				if err != nil {
+					panic(err)
+				}
+				dst = append(dst, eb...)
+			}
+		}
+	})
+}